@@ -0,0 +1,167 @@
+package webanalyze
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDOMSelectorsUnmarshalJSONNull(t *testing.T) {
+	var d DOMSelectors
+	if err := json.Unmarshal([]byte(`null`), &d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(d) != 0 {
+		t.Errorf("DOMSelectors = %v, want empty for a null dom field", d)
+	}
+}
+
+func TestDOMSelectorsUnmarshalJSONString(t *testing.T) {
+	var d DOMSelectors
+	if err := json.Unmarshal([]byte(`"#wpadminbar"`), &d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	cond, ok := d["#wpadminbar"]
+	if !ok || !cond.Exists {
+		t.Errorf("DOMSelectors = %v, want an existence check for #wpadminbar", d)
+	}
+}
+
+func TestDOMSelectorsUnmarshalJSONArray(t *testing.T) {
+	var d DOMSelectors
+	if err := json.Unmarshal([]byte(`["#a", "#b"]`), &d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(d) != 2 || !d["#a"].Exists || !d["#b"].Exists {
+		t.Errorf("DOMSelectors = %v, want existence checks for #a and #b", d)
+	}
+}
+
+func TestDOMSelectorsUnmarshalJSONObject(t *testing.T) {
+	var d DOMSelectors
+	raw := `{".generator": {"attributes": {"content": "WordPress"}}, "title": {"text": "My Shop"}}`
+	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if got := d[".generator"].Attributes["content"]; got != "WordPress" {
+		t.Errorf(".generator attributes[content] = %q, want %q", got, "WordPress")
+	}
+	if got := d["title"].Text; got != "My Shop" {
+		t.Errorf("title text = %q, want %q", got, "My Shop")
+	}
+}
+
+func TestCompileDOMRegexesExistsCheck(t *testing.T) {
+	selectors := DOMSelectors{"#wpadminbar": {Exists: true}}
+	compiled := compileDOMRegexes(selectors)
+
+	if len(compiled) != 1 || !compiled[0].Exists || compiled[0].Selector != "#wpadminbar" {
+		t.Fatalf("compileDOMRegexes() = %v, want one existence entry for #wpadminbar", compiled)
+	}
+}
+
+func TestCompileDOMRegexesAttributeAndText(t *testing.T) {
+	selectors := DOMSelectors{
+		".generator": {Attributes: map[string]string{"content": "WordPress \\;version:\\1"}},
+		"title":      {Text: "My Shop"},
+	}
+	compiled := compileDOMRegexes(selectors)
+
+	var sawAttr, sawText bool
+	for _, c := range compiled {
+		switch {
+		case c.Selector == ".generator" && c.Attribute == "content":
+			sawAttr = true
+			if c.Version != "\\1" {
+				t.Errorf("attribute version template = %q, want %q", c.Version, "\\1")
+			}
+			if !c.Regexp.MatchString("WordPress 6.0") {
+				t.Error("expected compiled attribute regex to match \"WordPress 6.0\"")
+			}
+		case c.Selector == "title":
+			sawText = true
+			if !c.Regexp.MatchString("My Shop") {
+				t.Error("expected compiled text regex to match \"My Shop\"")
+			}
+		}
+	}
+	if !sawAttr {
+		t.Error("expected an attribute regex entry for .generator")
+	}
+	if !sawText {
+		t.Error("expected a text regex entry for title")
+	}
+}
+
+func TestFindInDOMExistsAndAttribute(t *testing.T) {
+	app := &App{}
+	app.DOMRegex = compileDOMRegexes(DOMSelectors{
+		"#wpadminbar": {Exists: true},
+		".generator":  {Attributes: map[string]string{"content": "WordPress"}},
+	})
+
+	elements := []DOMElement{
+		{Selector: "#wpadminbar"},
+		{Selector: ".generator", Attributes: map[string]string{"content": "WordPress 6.0"}},
+	}
+
+	matches, _ := app.FindInDOM(elements)
+	if len(matches) != 2 {
+		t.Fatalf("FindInDOM() matches = %v, want 2", matches)
+	}
+}
+
+func TestFindInCSS(t *testing.T) {
+	app := &App{CSSRegex: compileRegexes(StringArray{"\\.wp-block-"})}
+
+	matches, _ := app.FindInCSS(".wp-block-group { display: flex; }")
+	if len(matches) == 0 {
+		t.Error("expected FindInCSS to match wp-block- in the stylesheet")
+	}
+
+	matches, _ = app.FindInCSS("body { color: red; }")
+	if len(matches) != 0 {
+		t.Error("expected FindInCSS not to match unrelated CSS")
+	}
+}
+
+func TestFindInText(t *testing.T) {
+	app := &App{TextRegex: compileRegexes(StringArray{"Powered by WordPress"})}
+
+	matches, _ := app.FindInText("This site is Powered by WordPress.")
+	if len(matches) == 0 {
+		t.Error("expected FindInText to match the visible page text")
+	}
+}
+
+func TestFindInCSSResolvesVersion(t *testing.T) {
+	app := &App{CSSRegex: compileRegexes(StringArray{"bootstrap-v([0-9.]+)\\;version:\\1"})}
+
+	_, version := app.FindInCSS("/* bootstrap-v5.1.3 */")
+	if version != "5.1.3" {
+		t.Errorf("version = %q, want %q", version, "5.1.3")
+	}
+}
+
+func TestFindInTextResolvesVersion(t *testing.T) {
+	app := &App{TextRegex: compileRegexes(StringArray{"WordPress ([0-9.]+)\\;version:\\1"})}
+
+	_, version := app.FindInText("Running WordPress 6.2.1 on this site.")
+	if version != "6.2.1" {
+		t.Errorf("version = %q, want %q", version, "6.2.1")
+	}
+}
+
+func TestFindInJS(t *testing.T) {
+	app := &App{}
+	app.JS = map[string]StringArray{"React.version": {"\\;version:\\0"}}
+	app.JSRegex = compileNamedRegexes(map[string]string{"React.version": ".+\\;version:\\0"})
+
+	matches, version := app.FindInJS(map[string]string{"React.version": "18.2.0"})
+	if len(matches) == 0 {
+		t.Fatal("expected FindInJS to match the extracted React.version variable")
+	}
+	if version != "18.2.0" {
+		t.Errorf("version = %q, want %q", version, "18.2.0")
+	}
+}
@@ -0,0 +1,264 @@
+package webanalyze
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// PrefilterIndex narrows, by exact literal substrings, which apps are worth
+// running their full regexes against for a given piece of scanned content.
+// It trades a small amount of build-time analysis for avoiding an
+// O(apps x regexes) scan on every response.
+type PrefilterIndex struct {
+	ac         *acMatcher
+	literalApp map[int][]string // ac pattern id -> app names requiring it
+	unindexed  []string         // apps with at least one regex we can't safely prefilter
+}
+
+// buildPrefilterIndex walks every compiled regex across all fingerprint
+// fields of every app, extracts a literal substring each regex requires via
+// regexp/syntax, and builds a multi-pattern matcher over them. Any app with
+// a regex we can't extract a required literal from is always treated as a
+// candidate, so prefiltering never causes a false negative.
+func buildPrefilterIndex(apps map[string]App) *PrefilterIndex {
+	var patterns []string
+	literalApp := make(map[int][]string)
+	seen := make(map[string]int)
+	unindexedSet := make(map[string]struct{})
+
+	addRegex := func(name string, re *regexp.Regexp) {
+		if re == nil {
+			return
+		}
+		lit, ok := requiredLiteral(re)
+		if !ok {
+			unindexedSet[name] = struct{}{}
+			return
+		}
+		id, exists := seen[lit]
+		if !exists {
+			id = len(patterns)
+			patterns = append(patterns, lit)
+			seen[lit] = id
+		}
+		literalApp[id] = appendUnique(literalApp[id], name)
+	}
+
+	for name, app := range apps {
+		for _, set := range [][]AppRegexp{
+			app.HTMLRegex, app.ScriptRegex, app.URLRegex,
+			app.HeaderRegex, app.MetaRegex, app.CookieRegex,
+			app.CSSRegex, app.JSRegex, app.TextRegex,
+		} {
+			for _, re := range set {
+				addRegex(name, re.Regexp)
+			}
+		}
+
+		for _, dre := range app.DOMRegex {
+			if dre.Exists {
+				continue // existence-only checks have no content regex to index
+			}
+			addRegex(name, dre.Regexp)
+		}
+	}
+
+	unindexed := make([]string, 0, len(unindexedSet))
+	for name := range unindexedSet {
+		unindexed = append(unindexed, name)
+	}
+
+	return &PrefilterIndex{
+		ac:         buildACMatcher(patterns),
+		literalApp: literalApp,
+		unindexed:  unindexed,
+	}
+}
+
+// Candidates returns the set of app names worth running full regexes
+// against for content: apps with a required literal found in content, plus
+// any app that couldn't be safely prefiltered.
+func (idx *PrefilterIndex) Candidates(content []byte) map[string]struct{} {
+	candidates := make(map[string]struct{}, len(idx.unindexed))
+	for _, name := range idx.unindexed {
+		candidates[name] = struct{}{}
+	}
+
+	for _, id := range idx.ac.Match(content) {
+		for _, name := range idx.literalApp[id] {
+			candidates[name] = struct{}{}
+		}
+	}
+
+	return candidates
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// requiredLiteral extracts a single literal substring that must appear
+// (case-insensitively) in any input the regex matches, by walking its
+// parsed syntax tree for the longest run of literal runes that isn't
+// optional, repeated, or part of an alternation. It returns ok=false when no
+// such literal can be proven required, e.g. the regex is built only from
+// character classes or alternates between distinct literals, and also when
+// the literal is shorter than 3 bytes or contains non-ASCII characters.
+func requiredLiteral(re *regexp.Regexp) (string, bool) {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	parsed = parsed.Simplify()
+
+	best := longestMandatoryLiteral(parsed)
+	if len(best) < 3 { // too short to meaningfully narrow candidates
+		return "", false
+	}
+	for i := 0; i < len(best); i++ {
+		if best[i] >= 0x80 { // acMatcher only case-folds ASCII bytes of content
+			return "", false
+		}
+	}
+	return strings.ToLower(best), true
+}
+
+// longestMandatoryLiteral returns the longest substring that must literally
+// appear in any string the (sub)expression matches. It only descends into
+// operators that can't skip or vary the text: literals, single-child
+// captures, and concatenation. Stars, character classes, anchors, and
+// alternation don't guarantee a literal, so those subtrees contribute
+// nothing — note that syntax.Parse already factors a shared literal prefix
+// out of an alternation into a sibling OpLiteral under OpConcat (e.g.
+// "wp-content|wp-includes" parses as Concat(Literal("wp-"), Alternate(...))),
+// so the OpConcat case below picks it up without needing to inspect
+// OpAlternate branches itself.
+func longestMandatoryLiteral(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return string(re.Rune)
+	case syntax.OpCapture:
+		if len(re.Sub) == 1 {
+			return longestMandatoryLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var best string
+		for _, sub := range re.Sub {
+			if lit := longestMandatoryLiteral(sub); len(lit) > len(best) {
+				best = lit
+			}
+		}
+		return best
+	}
+	return ""
+}
+
+// acNode is one state in the Aho-Corasick trie: its transitions, failure
+// link, and the ids of any patterns that end at this state.
+type acNode struct {
+	children map[byte]int
+	fail     int
+	output   []int
+}
+
+func newACNode() acNode {
+	return acNode{children: make(map[byte]int)}
+}
+
+// acMatcher is a simplified Aho-Corasick automaton: a trie of lower-cased
+// literal patterns with failure links, letting a scan find every pattern
+// present in content in a single pass.
+type acMatcher struct {
+	nodes []acNode
+}
+
+// buildACMatcher builds the trie and its failure links via a BFS over the
+// trie in insertion order, per the standard Aho-Corasick construction.
+func buildACMatcher(patterns []string) *acMatcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	m := &acMatcher{nodes: []acNode{newACNode()}}
+
+	for id, pat := range patterns {
+		cur := 0
+		for i := 0; i < len(pat); i++ {
+			c := pat[i]
+			next, ok := m.nodes[cur].children[c]
+			if !ok {
+				m.nodes = append(m.nodes, newACNode())
+				next = len(m.nodes) - 1
+				m.nodes[cur].children[c] = next
+			}
+			cur = next
+		}
+		m.nodes[cur].output = append(m.nodes[cur].output, id)
+	}
+
+	var queue []int
+	for _, next := range m.nodes[0].children {
+		m.nodes[next].fail = 0
+		queue = append(queue, next)
+	}
+
+	for qi := 0; qi < len(queue); qi++ {
+		cur := queue[qi]
+		for c, next := range m.nodes[cur].children {
+			queue = append(queue, next)
+
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].children[c]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if child, ok := m.nodes[fail].children[c]; ok && child != next {
+				fail = child
+			}
+
+			m.nodes[next].fail = fail
+			m.nodes[next].output = append(m.nodes[next].output, m.nodes[fail].output...)
+		}
+	}
+
+	return m
+}
+
+// Match returns, for each byte position scanned, the ids of every pattern
+// ending there, in one linear pass over content.
+func (m *acMatcher) Match(content []byte) []int {
+	if m == nil {
+		return nil
+	}
+
+	var hits []int
+	cur := 0
+	for _, raw := range content {
+		c := raw
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		for cur != 0 {
+			if _, ok := m.nodes[cur].children[c]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if next, ok := m.nodes[cur].children[c]; ok {
+			cur = next
+		}
+
+		hits = append(hits, m.nodes[cur].output...)
+	}
+
+	return hits
+}
@@ -0,0 +1,92 @@
+package webanalyze
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestACMatcherFindsAllPatterns(t *testing.T) {
+	m := buildACMatcher([]string{"jquery", "react", "wordpress"})
+
+	hits := m.Match([]byte("powered by WordPress, loaded jQuery.min.js"))
+	if len(hits) == 0 {
+		t.Fatal("expected at least one match, got none")
+	}
+
+	found := make(map[int]bool)
+	for _, id := range hits {
+		found[id] = true
+	}
+	if !found[0] {
+		t.Error("expected to find pattern 0 (jquery)")
+	}
+	if !found[2] {
+		t.Error("expected to find pattern 2 (wordpress)")
+	}
+	if found[1] {
+		t.Error("did not expect to find pattern 1 (react)")
+	}
+}
+
+func TestRequiredLiteralSkipsUnanchoredRegex(t *testing.T) {
+	apps := map[string]App{
+		"HasLiteral": {
+			HeaderRegex: compileNamedRegexes(map[string]string{"X-Powered-By": "WordPress"}),
+		},
+		"NoLiteral": {
+			HeaderRegex: compileNamedRegexes(map[string]string{"X-Powered-By": ".*"}),
+		},
+	}
+
+	idx := buildPrefilterIndex(apps)
+
+	candidates := idx.Candidates([]byte("x-powered-by: nothing interesting here"))
+	if _, ok := candidates["NoLiteral"]; !ok {
+		t.Error("expected unindexable app to always be a candidate")
+	}
+	if _, ok := candidates["HasLiteral"]; ok {
+		t.Error("did not expect HasLiteral to be a candidate for unrelated content")
+	}
+
+	candidates = idx.Candidates([]byte("running WordPress 6.0"))
+	if _, ok := candidates["HasLiteral"]; !ok {
+		t.Error("expected HasLiteral to be a candidate when its literal is present")
+	}
+}
+
+func TestRequiredLiteralExtractsFactoredAlternatePrefix(t *testing.T) {
+	re := regexp.MustCompile("(?i)wp-content|wp-includes")
+
+	lit, ok := requiredLiteral(re)
+	if !ok {
+		t.Fatal("expected a literal to be extracted via the factored-out prefix")
+	}
+	if lit != "wp-" {
+		t.Errorf("requiredLiteral() = %q, want %q", lit, "wp-")
+	}
+}
+
+func TestRequiredLiteralSkipsAlternationWithoutCommonPrefix(t *testing.T) {
+	re := regexp.MustCompile("(?i)jquery|react")
+
+	if _, ok := requiredLiteral(re); ok {
+		t.Error("expected no literal when alternation branches share no prefix")
+	}
+}
+
+func TestPrefilterIndexCandidatesForAlternationRegex(t *testing.T) {
+	apps := map[string]App{
+		"WordPress": {
+			HTMLRegex: compileRegexes(StringArray{"wp-content|wp-includes"}),
+		},
+	}
+
+	idx := buildPrefilterIndex(apps)
+
+	if _, ok := idx.Candidates([]byte("<script src=/wp-includes/js/jquery.js>"))["WordPress"]; !ok {
+		t.Error("expected WordPress to be a candidate via the wp- common prefix")
+	}
+	if _, ok := idx.Candidates([]byte("<html>nothing relevant</html>"))["WordPress"]; ok {
+		t.Error("did not expect WordPress to be a candidate for unrelated content")
+	}
+}
@@ -0,0 +1,173 @@
+package webanalyze
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Match represents one detected technology: the app name, the version
+// recovered from its fingerprints (if any), and the confidence of the
+// detection, from 0 to 100.
+type Match struct {
+	AppName    string
+	Version    string
+	Confidence int
+}
+
+// Result holds both a scan's directly-detected matches and the same set
+// after Resolve has added implied apps and dropped any whose requirements
+// weren't met.
+type Result struct {
+	Matches         []Match
+	ResolvedMatches []Match
+}
+
+// ImpliedApp is one entry from an App's Implies list, with the confidence
+// parsed out of the "AppName\;confidence:50" syntax wappalyzer uses,
+// defaulting to full confidence when no marker is present.
+type ImpliedApp struct {
+	Name       string
+	Confidence int
+}
+
+// parseImplies parses an App's raw Implies entries into ImpliedApp values.
+func parseImplies(implies StringArray) []ImpliedApp {
+	list := make([]ImpliedApp, 0, len(implies))
+
+	for _, entry := range implies {
+		splitted := strings.Split(entry, "\\;")
+
+		confidence := 100
+		if len(splitted) > 1 && strings.HasPrefix(splitted[1], "confidence:") {
+			if c, err := strconv.Atoi(splitted[1][len("confidence:"):]); err == nil {
+				confidence = c
+			}
+		}
+
+		list = append(list, ImpliedApp{Name: splitted[0], Confidence: confidence})
+	}
+
+	return list
+}
+
+// Resolve takes the matches directly detected in a scan and returns a
+// Result exposing both that raw set and the resolved one: implied apps
+// added with confidence propagated (multiplied) along the Implies chain,
+// and any app whose requires/requiresCategory dependency isn't present
+// removed.
+func (wa *WebAnalyzer) Resolve(matches []Match) *Result {
+	return &Result{
+		Matches:         matches,
+		ResolvedMatches: wa.resolveMatches(matches),
+	}
+}
+
+// resolveMatches does the actual implies/requires resolution work for
+// Resolve, returning just the resolved slice.
+func (wa *WebAnalyzer) resolveMatches(matches []Match) []Match {
+	byName := make(map[string]Match, len(matches))
+	for _, m := range matches {
+		byName[m.AppName] = m
+	}
+
+	wa.addImplied(byName)
+	wa.dropUnmetRequirements(byName)
+
+	resolved := make([]Match, 0, len(byName))
+	for _, m := range byName {
+		resolved = append(resolved, m)
+	}
+	return resolved
+}
+
+// addImplied walks the Implies chain of every match already in byName,
+// breadth-first, adding any implied app not already present (or replacing
+// it if this chain yields higher confidence) with confidence propagated
+// from the implying app.
+func (wa *WebAnalyzer) addImplied(byName map[string]Match) {
+	queue := make([]string, 0, len(byName))
+	for name := range byName {
+		queue = append(queue, name)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		app, ok := wa.appDefs.Apps[name]
+		if !ok {
+			continue
+		}
+
+		for _, implied := range app.ImpliesResolved {
+			confidence := byName[name].Confidence * implied.Confidence / 100
+
+			if existing, present := byName[implied.Name]; present && existing.Confidence >= confidence {
+				continue
+			}
+
+			byName[implied.Name] = Match{AppName: implied.Name, Confidence: confidence}
+			queue = append(queue, implied.Name)
+		}
+	}
+}
+
+// dropUnmetRequirements repeatedly removes any app whose requires or
+// requiresCategory dependency isn't satisfied by another match in byName,
+// until a pass removes nothing — so a chain of requirements (A requires B
+// requires C) resolves correctly regardless of map iteration order.
+func (wa *WebAnalyzer) dropUnmetRequirements(byName map[string]Match) {
+	for {
+		var unmet []string
+		for name := range byName {
+			app, ok := wa.appDefs.Apps[name]
+			if ok && !wa.requirementsMet(app, byName) {
+				unmet = append(unmet, name)
+			}
+		}
+		if len(unmet) == 0 {
+			return
+		}
+		for _, name := range unmet {
+			delete(byName, name)
+		}
+	}
+}
+
+// requirementsMet reports whether every requires and requiresCategory entry
+// of app is satisfied by another match present in byName.
+func (wa *WebAnalyzer) requirementsMet(app App, byName map[string]Match) bool {
+	for _, req := range app.Requires {
+		if _, present := byName[req]; !present {
+			return false
+		}
+	}
+	for _, cat := range app.RequiresCategory {
+		if !wa.hasMatchInCategory(byName, cat) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasMatchInCategory reports whether any app in byName belongs to category,
+// given either as a category id (as in Cats) or as a category name.
+func (wa *WebAnalyzer) hasMatchInCategory(byName map[string]Match, category string) bool {
+	for name := range byName {
+		app, ok := wa.appDefs.Apps[name]
+		if !ok {
+			continue
+		}
+		for _, c := range app.Cats {
+			if c == category {
+				return true
+			}
+		}
+		for _, c := range app.CatNames {
+			if c == category {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package webanalyze
+
+import "testing"
+
+func newTestWebAnalyzer(apps map[string]App) *WebAnalyzer {
+	return &WebAnalyzer{appDefs: AppsDefinition{Apps: apps}}
+}
+
+func TestResolveAddsImpliedAppsWithPropagatedConfidence(t *testing.T) {
+	wa := newTestWebAnalyzer(map[string]App{
+		"WordPress": {ImpliesResolved: []ImpliedApp{{Name: "PHP", Confidence: 100}, {Name: "MySQL", Confidence: 50}}},
+		"PHP":       {},
+		"MySQL":     {},
+	})
+
+	result := wa.Resolve([]Match{{AppName: "WordPress", Confidence: 100}})
+
+	byName := make(map[string]Match)
+	for _, m := range result.ResolvedMatches {
+		byName[m.AppName] = m
+	}
+
+	if _, ok := byName["PHP"]; !ok {
+		t.Fatal("expected PHP to be added via implies")
+	}
+	if got := byName["MySQL"].Confidence; got != 50 {
+		t.Errorf("MySQL confidence = %d, want 50", got)
+	}
+}
+
+func TestResolveDropsAppsWithUnmetRequirement(t *testing.T) {
+	wa := newTestWebAnalyzer(map[string]App{
+		"WooCommerce": {Requires: StringArray{"WordPress"}},
+		"WordPress":   {},
+	})
+
+	result := wa.Resolve([]Match{{AppName: "WooCommerce", Confidence: 100}})
+
+	for _, m := range result.ResolvedMatches {
+		if m.AppName == "WooCommerce" {
+			t.Fatal("expected WooCommerce to be dropped, its requirement (WordPress) was not matched")
+		}
+	}
+}
+
+func TestResolveKeepsAppWhenRequirementIsMet(t *testing.T) {
+	wa := newTestWebAnalyzer(map[string]App{
+		"WooCommerce": {Requires: StringArray{"WordPress"}},
+		"WordPress":   {},
+	})
+
+	result := wa.Resolve([]Match{
+		{AppName: "WooCommerce", Confidence: 100},
+		{AppName: "WordPress", Confidence: 100},
+	})
+
+	found := false
+	for _, m := range result.ResolvedMatches {
+		if m.AppName == "WooCommerce" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected WooCommerce to be kept, its requirement (WordPress) was matched")
+	}
+}
+
+func TestResolveExposesBothRawAndResolvedMatches(t *testing.T) {
+	wa := newTestWebAnalyzer(map[string]App{
+		"WordPress":   {ImpliesResolved: []ImpliedApp{{Name: "PHP", Confidence: 100}}},
+		"PHP":         {},
+		"WooCommerce": {Requires: StringArray{"Shopify"}}, // unmet, should be dropped from resolved only
+	})
+
+	raw := []Match{
+		{AppName: "WordPress", Confidence: 100},
+		{AppName: "WooCommerce", Confidence: 100},
+	}
+
+	result := wa.Resolve(raw)
+
+	if len(result.Matches) != len(raw) {
+		t.Fatalf("Matches = %v, want the unmodified raw input %v", result.Matches, raw)
+	}
+	for i, m := range result.Matches {
+		if m != raw[i] {
+			t.Errorf("Matches[%d] = %v, want %v (raw match set must be untouched)", i, m, raw[i])
+		}
+	}
+
+	resolvedNames := make(map[string]bool)
+	for _, m := range result.ResolvedMatches {
+		resolvedNames[m.AppName] = true
+	}
+	if !resolvedNames["PHP"] {
+		t.Error("expected ResolvedMatches to include PHP, implied by WordPress")
+	}
+	if resolvedNames["WooCommerce"] {
+		t.Error("expected ResolvedMatches to drop WooCommerce, its requirement (Shopify) was not matched")
+	}
+	if !resolvedNames["WordPress"] {
+		t.Error("expected ResolvedMatches to still include the directly-detected WordPress")
+	}
+}
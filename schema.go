@@ -0,0 +1,210 @@
+package webanalyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WappalyzerMirrors is the ordered list of known upstream locations for the
+// fingerprint schema. FetchLatest tries each in turn until one succeeds,
+// falling back to the next on any error. WappalyzerURL, the historical
+// pinned single-file location, is kept first for backward compatibility.
+var WappalyzerMirrors = []string{
+	WappalyzerURL,
+	"https://raw.githubusercontent.com/AliasIO/wappalyzer/master/src",
+	"https://raw.githubusercontent.com/dochne/wappalyzer/master/src/technologies.json",
+	"https://raw.githubusercontent.com/projectdiscovery/wappalyzergo/main/technologies.json",
+}
+
+// schemaShards lists the letter shards used by the sharded technologies
+// layout, plus the "_" shard upstream uses for non-alphabetic app names.
+var schemaShards = append(strings.Split("abcdefghijklmnopqrstuvwxyz", ""), "_")
+
+// SchemaLoader decodes a fingerprint schema, in whatever on-disk layout it
+// is stored in, into an AppsDefinition.
+type SchemaLoader interface {
+	Load(src string) (*AppsDefinition, error)
+}
+
+// LegacySingleFile loads the historical, pinned layout: a single
+// technologies.json file containing both "technologies" and "categories".
+type LegacySingleFile struct{}
+
+func (LegacySingleFile) Load(src string) (*AppsDefinition, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var defs AppsDefinition
+	if err := json.NewDecoder(f).Decode(&defs); err != nil {
+		return nil, err
+	}
+	return &defs, nil
+}
+
+// ShardedByLetter loads the layout used by current upstream Wappalyzer
+// forks, where technologies are split across src/technologies/a.json ..
+// z.json (plus a "_" shard) next to a shared categories.json.
+type ShardedByLetter struct{}
+
+func (ShardedByLetter) Load(src string) (*AppsDefinition, error) {
+	defs := &AppsDefinition{
+		Apps: make(map[string]App),
+		Cats: make(map[string]Category),
+	}
+
+	if f, err := os.Open(filepath.Join(src, "categories.json")); err == nil {
+		err := json.NewDecoder(f).Decode(&defs.Cats)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding categories.json: %w", err)
+		}
+	}
+
+	for _, letter := range schemaShards {
+		shardFile := filepath.Join(src, "technologies", letter+".json")
+
+		f, err := os.Open(shardFile)
+		if err != nil {
+			continue // not every shard is present
+		}
+
+		var apps map[string]App
+		err = json.NewDecoder(f).Decode(&apps)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", shardFile, err)
+		}
+
+		for name, app := range apps {
+			defs.Apps[name] = app
+		}
+	}
+
+	return defs, nil
+}
+
+// detectSchemaLoader picks a SchemaLoader based on the shape of src: a path
+// or URL ending in .json is the legacy single-file layout, anything else is
+// treated as the base of a sharded layout.
+func detectSchemaLoader(src string) SchemaLoader {
+	if strings.HasSuffix(src, ".json") {
+		return LegacySingleFile{}
+	}
+	return ShardedByLetter{}
+}
+
+// Fetcher pulls the fingerprint schema from a remote source and writes it to
+// a local path in the legacy single-file shape, merging multi-file (sharded)
+// layouts along the way.
+type Fetcher interface {
+	Fetch(from, to string) error
+}
+
+// HTTPFetcher fetches the schema over HTTP(S), auto-detecting whether `from`
+// points at a single legacy file or the base URL of a sharded layout.
+type HTTPFetcher struct{}
+
+func (HTTPFetcher) Fetch(from, to string) error {
+	if strings.HasSuffix(from, ".json") {
+		return httpDownload(from, to)
+	}
+	return httpDownloadSharded(from, to)
+}
+
+// httpDownload copies a single remote file to a local path verbatim.
+func httpDownload(from, to string) error {
+	resp, err := http.Get(from)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", from, resp.Status)
+	}
+
+	f, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// httpDownloadSharded fetches categories.json and every technologies/*.json
+// shard from the base URL `from`, merges them, and writes the result to `to`
+// in the legacy single-file shape so existing loaders keep working.
+func httpDownloadSharded(from, to string) error {
+	defs := AppsDefinition{
+		Apps: make(map[string]App),
+		Cats: make(map[string]Category),
+	}
+
+	// categories.json is optional in some forks; don't fail the whole fetch
+	// over it, matching ShardedByLetter.Load's tolerance for a missing
+	// local categories.json.
+	_ = httpDecodeInto(from+"/categories.json", &defs.Cats)
+
+	for _, letter := range schemaShards {
+		var apps map[string]App
+		err := httpDecodeInto(from+"/technologies/"+letter+".json", &apps)
+		if err != nil {
+			continue // not every shard is present upstream
+		}
+		for name, app := range apps {
+			defs.Apps[name] = app
+		}
+	}
+
+	if len(defs.Apps) == 0 {
+		return fmt.Errorf("no technology shards found at %s/technologies", from)
+	}
+
+	f, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(defs)
+}
+
+func httpDecodeInto(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// FetchLatest downloads the fingerprint schema to `to`, trying each of
+// WappalyzerMirrors in turn and falling back to the next on error.
+func FetchLatest(to string) error {
+	var lastErr error
+
+	for _, mirror := range WappalyzerMirrors {
+		if err := (HTTPFetcher{}).Fetch(mirror, to); err == nil {
+			return nil
+		} else {
+			lastErr = err
+		}
+	}
+
+	return fmt.Errorf("all wappalyzer mirrors failed, last error: %w", lastErr)
+}
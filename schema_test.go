@@ -0,0 +1,159 @@
+package webanalyze
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShardedByLetterLoadMergesShards(t *testing.T) {
+	dir := t.TempDir()
+	writeJSONFile(t, filepath.Join(dir, "categories.json"), map[string]Category{"1": {Name: "CMS"}})
+	writeJSONFile(t, filepath.Join(dir, "technologies", "a.json"), map[string]App{"Apache": {}})
+	writeJSONFile(t, filepath.Join(dir, "technologies", "w.json"), map[string]App{"WordPress": {}})
+
+	defs, err := (ShardedByLetter{}).Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, ok := defs.Apps["Apache"]; !ok {
+		t.Error("expected Apache from shard a.json to be merged in")
+	}
+	if _, ok := defs.Apps["WordPress"]; !ok {
+		t.Error("expected WordPress from shard w.json to be merged in")
+	}
+	if defs.Cats["1"].Name != "CMS" {
+		t.Errorf("Cats[\"1\"].Name = %q, want %q", defs.Cats["1"].Name, "CMS")
+	}
+}
+
+func TestShardedByLetterLoadToleratesMissingCategories(t *testing.T) {
+	dir := t.TempDir()
+	writeJSONFile(t, filepath.Join(dir, "technologies", "a.json"), map[string]App{"Apache": {}})
+
+	defs, err := (ShardedByLetter{}).Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want nil even without categories.json", err)
+	}
+	if _, ok := defs.Apps["Apache"]; !ok {
+		t.Error("expected Apache to still be loaded")
+	}
+}
+
+func TestHTTPFetcherFetchDetectsShardedBaseURL(t *testing.T) {
+	srv := newShardedTestServer(t, true)
+	defer srv.Close()
+
+	to := filepath.Join(t.TempDir(), "technologies.json")
+	if err := (HTTPFetcher{}).Fetch(srv.URL, to); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	defs, err := (LegacySingleFile{}).Load(to)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := defs.Apps["Apache"]; !ok {
+		t.Error("expected Apache from the a.json shard to be merged into the fetched file")
+	}
+	if _, ok := defs.Apps["WordPress"]; !ok {
+		t.Error("expected WordPress from the w.json shard to be merged into the fetched file")
+	}
+	if defs.Cats["1"].Name != "CMS" {
+		t.Errorf("Cats[\"1\"].Name = %q, want %q", defs.Cats["1"].Name, "CMS")
+	}
+}
+
+func TestHTTPFetcherFetchToleratesMissingCategories(t *testing.T) {
+	srv := newShardedTestServer(t, false)
+	defer srv.Close()
+
+	to := filepath.Join(t.TempDir(), "technologies.json")
+	if err := (HTTPFetcher{}).Fetch(srv.URL, to); err != nil {
+		t.Fatalf("Fetch() error = %v, want nil even with a 404 categories.json", err)
+	}
+
+	defs, err := (LegacySingleFile{}).Load(to)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := defs.Apps["Apache"]; !ok {
+		t.Error("expected Apache from the a.json shard to still be merged in")
+	}
+}
+
+func TestFetchLatestFallsThroughToShardedMirror(t *testing.T) {
+	deadSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer deadSrv.Close()
+
+	shardedSrv := newShardedTestServer(t, true)
+	defer shardedSrv.Close()
+
+	original := WappalyzerMirrors
+	WappalyzerMirrors = []string{deadSrv.URL + "/technologies.json", shardedSrv.URL}
+	defer func() { WappalyzerMirrors = original }()
+
+	to := filepath.Join(t.TempDir(), "technologies.json")
+	if err := FetchLatest(to); err != nil {
+		t.Fatalf("FetchLatest() error = %v", err)
+	}
+
+	defs, err := (LegacySingleFile{}).Load(to)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := defs.Apps["Apache"]; !ok {
+		t.Error("expected FetchLatest to fall through to the sharded mirror and merge its shards")
+	}
+}
+
+// newShardedTestServer serves a minimal sharded layout (categories.json,
+// technologies/a.json, technologies/w.json). When withCategories is false,
+// categories.json 404s, exercising the tolerant-missing-categories path.
+func newShardedTestServer(t *testing.T, withCategories bool) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/categories.json", func(w http.ResponseWriter, r *http.Request) {
+		if !withCategories {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]Category{"1": {Name: "CMS"}})
+	})
+	mux.HandleFunc("/technologies/a.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]App{"Apache": {}})
+	})
+	mux.HandleFunc("/technologies/w.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]App{"WordPress": {}})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func writeJSONFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll(%q) error = %v", filepath.Dir(path), err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create(%q) error = %v", path, err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package webanalyze
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ResolveVersion expands a wappalyzer version template against the capture
+// groups of a single regex match (in the shape returned by
+// regexp.Regexp.FindStringSubmatch: match[0] is the full match, match[1:]
+// are the capture groups).
+//
+// Templates may contain back-references (\1, \2, ... \N) to groups in
+// match, and ternary expressions of the form \N?value_if_matched:value_if_not.
+// Ternaries chained in the "not matched" branch (\1?a:\2?b:c) are resolved
+// recursively, so the first group with a non-empty match wins.
+func ResolveVersion(match []string, template string) string {
+	if template == "" {
+		return ""
+	}
+	return resolveTernary(match, template)
+}
+
+// versionFromMatches resolves template against every match in turn,
+// returning the last non-empty result — mirroring the existing convention
+// of letting the most recent match win when several are found.
+func versionFromMatches(matches [][]string, template string) string {
+	var v string
+	for _, m := range matches {
+		if resolved := ResolveVersion(m, template); resolved != "" {
+			v = resolved
+		}
+	}
+	return v
+}
+
+// resolveTernary resolves the leading \N?yes:no expression in template, if
+// any, recursing into whichever branch the back-reference selects, then
+// substitutes any remaining back-references verbatim.
+func resolveTernary(match []string, template string) string {
+	qm := strings.IndexByte(template, '?')
+	if qm <= 0 {
+		return substituteBackrefs(match, template)
+	}
+
+	backslash := strings.LastIndexByte(template[:qm], '\\')
+	if backslash == -1 {
+		return substituteBackrefs(match, template)
+	}
+	groupStr := template[backslash+1 : qm]
+	group, err := strconv.Atoi(groupStr)
+	if err != nil {
+		return substituteBackrefs(match, template)
+	}
+
+	yes, no, ok := splitTernaryBranches(template[qm+1:])
+	if !ok {
+		return substituteBackrefs(match, template)
+	}
+
+	prefix := substituteBackrefs(match, template[:backslash])
+	if group < len(match) && match[group] != "" {
+		return prefix + resolveTernary(match, yes)
+	}
+	return prefix + resolveTernary(match, no)
+}
+
+// splitTernaryBranches splits "yes:no" on its first colon.
+func splitTernaryBranches(s string) (yes, no string, ok bool) {
+	if i := strings.IndexByte(s, ':'); i != -1 {
+		return s[:i], s[i+1:], true
+	}
+	return "", "", false
+}
+
+// substituteBackrefs replaces every \N in template with the corresponding
+// capture group from match. Back-references to groups outside the match
+// resolve to the empty string; anything else is copied verbatim.
+func substituteBackrefs(match []string, template string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '\\' || i+1 >= len(template) {
+			b.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+		for j < len(template) && template[j] >= '0' && template[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(c)
+			continue
+		}
+
+		group, _ := strconv.Atoi(template[i+1 : j])
+		if group < len(match) {
+			b.WriteString(match[group])
+		}
+		i = j - 1
+	}
+
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package webanalyze
+
+import "testing"
+
+func TestResolveVersionBackreference(t *testing.T) {
+	match := []string{"jquery-1.2.3.js", "1.2.3"}
+
+	if got := ResolveVersion(match, "\\1"); got != "1.2.3" {
+		t.Errorf("ResolveVersion() = %q, want %q", got, "1.2.3")
+	}
+}
+
+func TestResolveVersionMultiDigitBackreference(t *testing.T) {
+	match := make([]string, 13)
+	match[0] = "full match"
+	match[12] = "4.5.6"
+
+	if got := ResolveVersion(match, "\\12"); got != "4.5.6" {
+		t.Errorf("ResolveVersion() = %q, want %q", got, "4.5.6")
+	}
+}
+
+func TestResolveVersionTernary(t *testing.T) {
+	cases := []struct {
+		name     string
+		match    []string
+		template string
+		want     string
+	}{
+		{
+			name:     "group matched",
+			match:    []string{"full", "v2"},
+			template: "\\1?2.x:unknown",
+			want:     "2.x",
+		},
+		{
+			name:     "group not matched",
+			match:    []string{"full", ""},
+			template: "\\1?2.x:unknown",
+			want:     "unknown",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ResolveVersion(tc.match, tc.template); got != tc.want {
+				t.Errorf("ResolveVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveVersionChainedTernary(t *testing.T) {
+	template := "\\1?1.x:\\2?2.x:unknown"
+
+	cases := []struct {
+		name  string
+		match []string
+		want  string
+	}{
+		{name: "first group wins", match: []string{"full", "a", "b"}, want: "1.x"},
+		{name: "second group wins", match: []string{"full", "", "b"}, want: "2.x"},
+		{name: "neither matches", match: []string{"full", "", ""}, want: "unknown"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ResolveVersion(tc.match, template); got != tc.want {
+				t.Errorf("ResolveVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveVersionTernaryWithBackrefPrefix(t *testing.T) {
+	match := []string{"full", "1", "2"}
+
+	if got := ResolveVersion(match, "\\1-\\2?yes:no"); got != "1-yes" {
+		t.Errorf("ResolveVersion() = %q, want %q", got, "1-yes")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"regexp"
 	"strings"
 )
@@ -18,23 +17,34 @@ type StringArray []string
 
 // App type encapsulates all the data about an App from technologies.json
 type App struct {
-	Cats     StringArray            `json:"cats"`
-	CatNames []string               `json:"category_names"`
-	Cookies  map[string]string      `json:"cookies"`
-	Headers  map[string]string      `json:"headers"`
-	Meta     map[string]StringArray `json:"meta"`
-	HTML     StringArray            `json:"html"`
-	Script   StringArray            `json:"script"`
-	URL      StringArray            `json:"url"`
-	Website  string                 `json:"website"`
-	Implies  StringArray            `json:"implies"`
-
-	HTMLRegex   []AppRegexp `json:"-"`
-	ScriptRegex []AppRegexp `json:"-"`
-	URLRegex    []AppRegexp `json:"-"`
-	HeaderRegex []AppRegexp `json:"-"`
-	MetaRegex   []AppRegexp `json:"-"`
-	CookieRegex []AppRegexp `json:"-"`
+	Cats             StringArray            `json:"cats"`
+	CatNames         []string               `json:"category_names"`
+	Cookies          map[string]string      `json:"cookies"`
+	Headers          map[string]string      `json:"headers"`
+	Meta             map[string]StringArray `json:"meta"`
+	HTML             StringArray            `json:"html"`
+	Script           StringArray            `json:"script"`
+	URL              StringArray            `json:"url"`
+	Website          string                 `json:"website"`
+	Implies          StringArray            `json:"implies"`
+	Requires         StringArray            `json:"requires"`
+	RequiresCategory StringArray            `json:"requiresCategory"`
+	DOM              DOMSelectors           `json:"dom"`
+	CSS              StringArray            `json:"css"`
+	JS               map[string]StringArray `json:"js"`
+	Text             StringArray            `json:"text"`
+
+	HTMLRegex       []AppRegexp    `json:"-"`
+	ScriptRegex     []AppRegexp    `json:"-"`
+	URLRegex        []AppRegexp    `json:"-"`
+	HeaderRegex     []AppRegexp    `json:"-"`
+	MetaRegex       []AppRegexp    `json:"-"`
+	CookieRegex     []AppRegexp    `json:"-"`
+	DOMRegex        []AppDOMRegexp `json:"-"`
+	CSSRegex        []AppRegexp    `json:"-"`
+	JSRegex         []AppRegexp    `json:"-"`
+	TextRegex       []AppRegexp    `json:"-"`
+	ImpliesResolved []ImpliedApp   `json:"-"`
 }
 
 // Category names defined by wappalyzer
@@ -46,6 +56,8 @@ type Category struct {
 type AppsDefinition struct {
 	Apps map[string]App      `json:"technologies"`
 	Cats map[string]Category `json:"categories"`
+
+	index *PrefilterIndex
 }
 
 type AppRegexp struct {
@@ -54,6 +66,78 @@ type AppRegexp struct {
 	Version string
 }
 
+// DOMCondition describes how to confirm a match against a CSS selector: it is
+// satisfied either by the selector simply matching a node (Exists), by a
+// regex against one of the node's attributes, or by a regex against its text
+// content.
+type DOMCondition struct {
+	Exists     bool
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Text       string            `json:"text,omitempty"`
+}
+
+// DOMSelectors maps CSS selectors to the condition used to confirm a match,
+// as found in the wappalyzer "dom" fingerprint field.
+type DOMSelectors map[string]DOMCondition
+
+// UnmarshalJSON handles the three shapes wappalyzer uses for "dom": a bare
+// selector string, an array of selector strings (both treated as existence
+// checks), or an object mapping selectors to {attributes,text} conditions.
+func (d *DOMSelectors) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = nil
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*d = DOMSelectors{s: {Exists: true}}
+		return nil
+	}
+
+	var sa []string
+	if err := json.Unmarshal(data, &sa); err == nil {
+		m := make(DOMSelectors, len(sa))
+		for _, sel := range sa {
+			m[sel] = DOMCondition{Exists: true}
+		}
+		*d = m
+		return nil
+	}
+
+	var raw map[string]DOMCondition
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for sel, cond := range raw {
+		if len(cond.Attributes) == 0 && cond.Text == "" {
+			cond.Exists = true
+			raw[sel] = cond
+		}
+	}
+	*d = raw
+	return nil
+}
+
+// AppDOMRegexp is a compiled DOM fingerprint: a CSS selector together with
+// the compiled regex (if any) used to validate an attribute or the text
+// content of a matching node.
+type AppDOMRegexp struct {
+	Selector  string
+	Attribute string
+	Exists    bool
+	AppRegexp
+}
+
+// DOMElement represents a single node in the page DOM that matched one of an
+// app's CSS selectors, as resolved by the caller's HTML parser.
+type DOMElement struct {
+	Selector   string
+	Attributes map[string]string
+	Text       string
+}
+
 func (app *App) FindInHeaders(headers http.Header) (matches [][]string, version string) {
 	var v string
 
@@ -66,9 +150,98 @@ func (app *App) FindInHeaders(headers http.Header) (matches [][]string, version
 			if headerValue == "" {
 				continue
 			}
-			if m, version := findMatches(headerValue, []AppRegexp{hre}); len(m) > 0 {
+			if m, _ := findMatches(headerValue, []AppRegexp{hre}); len(m) > 0 {
+				matches = append(matches, m...)
+				if resolved := versionFromMatches(m, hre.Version); resolved != "" {
+					v = resolved
+				}
+			}
+		}
+	}
+	return matches, v
+}
+
+// FindInDOM matches an app's dom fingerprints against elements already
+// extracted from the page by the caller's HTML parser.
+func (app *App) FindInDOM(elements []DOMElement) (matches [][]string, version string) {
+	var v string
+
+	for _, dre := range app.DOMRegex {
+		for _, el := range elements {
+			if el.Selector != dre.Selector {
+				continue
+			}
+
+			if dre.Exists {
+				matches = append(matches, []string{el.Selector})
+				continue
+			}
+
+			target := el.Text
+			if dre.Attribute != "" {
+				target = el.Attributes[dre.Attribute]
+			}
+			if target == "" {
+				continue
+			}
+
+			if m, _ := findMatches(target, []AppRegexp{dre.AppRegexp}); len(m) > 0 {
 				matches = append(matches, m...)
-				v = version
+				if resolved := versionFromMatches(m, dre.Version); resolved != "" {
+					v = resolved
+				}
+			}
+		}
+	}
+	return matches, v
+}
+
+// FindInCSS matches an app's css fingerprints against the page's inline and
+// linked stylesheet content.
+func (app *App) FindInCSS(css string) (matches [][]string, version string) {
+	var v string
+
+	for _, cre := range app.CSSRegex {
+		if m, _ := findMatches(css, []AppRegexp{cre}); len(m) > 0 {
+			matches = append(matches, m...)
+			if resolved := versionFromMatches(m, cre.Version); resolved != "" {
+				v = resolved
+			}
+		}
+	}
+	return matches, v
+}
+
+// FindInText matches an app's text fingerprints against the page's visible
+// text content.
+func (app *App) FindInText(text string) (matches [][]string, version string) {
+	var v string
+
+	for _, tre := range app.TextRegex {
+		if m, _ := findMatches(text, []AppRegexp{tre}); len(m) > 0 {
+			matches = append(matches, m...)
+			if resolved := versionFromMatches(m, tre.Version); resolved != "" {
+				v = resolved
+			}
+		}
+	}
+	return matches, v
+}
+
+// FindInJS matches an app's js fingerprints against global JavaScript
+// variables extracted from the page, keyed by variable path (e.g. "React.version").
+func (app *App) FindInJS(vars map[string]string) (matches [][]string, version string) {
+	var v string
+
+	for _, jre := range app.JSRegex {
+		val, ok := vars[jre.Name]
+		if !ok || val == "" {
+			continue
+		}
+		if m, _ := findMatches(val, []AppRegexp{jre}); len(m) > 0 {
+			matches = append(matches, m...)
+			if resolved := versionFromMatches(m, jre.Version); resolved != "" {
+				v = resolved
 			}
 		}
 	}
@@ -103,30 +276,39 @@ func (t *StringArray) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// DownloadFile pulls the latest technologies.json file from the Wappalyzer github
+// DownloadFile pulls the latest fingerprint schema from the given source
+// using the default HTTPFetcher, auto-detecting single-file vs. sharded
+// layouts. Kept as a thin wrapper for backward compatibility.
 func DownloadFile(from, to string) error {
-	resp, err := http.Get(from)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	return (HTTPFetcher{}).Fetch(from, to)
+}
 
-	f, err := os.Create(to)
-	if err != nil {
+// load apps from io.Reader, in the legacy single-file shape
+func (wa *WebAnalyzer) loadApps(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&wa.appDefs); err != nil {
 		return err
 	}
 
-	_, err = io.Copy(f, resp.Body)
-	return err
+	return wa.compileApps()
 }
 
-// load apps from io.Reader
-func (wa *WebAnalyzer) loadApps(r io.Reader) error {
-	dec := json.NewDecoder(r)
-	if err := dec.Decode(&wa.appDefs); err != nil {
+// loadAppsFromSource loads apps/categories definitions from src, picking a
+// SchemaLoader based on whether src is a single legacy file or the base of a
+// sharded layout, then compiles their regexes.
+func (wa *WebAnalyzer) loadAppsFromSource(src string) error {
+	defs, err := detectSchemaLoader(src).Load(src)
+	if err != nil {
 		return err
 	}
 
+	wa.appDefs = *defs
+	return wa.compileApps()
+}
+
+// compileApps walks wa.appDefs.Apps, compiling every fingerprint field into
+// its *Regex counterpart and resolving category names.
+func (wa *WebAnalyzer) compileApps() error {
 	for key, value := range wa.appDefs.Apps {
 
 		app := wa.appDefs.Apps[key]
@@ -146,6 +328,19 @@ func (wa *WebAnalyzer) loadApps(r io.Reader) error {
 		}
 		app.MetaRegex = compileNamedRegexes(metaRegex)
 
+		// js fields follow the same shape as meta: a list of candidate
+		// regexes per variable path, joined into a single alternation
+		jsRegex := make(map[string]string)
+		for k, v := range app.JS {
+			jsRegex[k] = strings.Join(v, "|")
+		}
+		app.JSRegex = compileNamedRegexes(jsRegex)
+
+		app.CSSRegex = compileRegexes(value.CSS)
+		app.TextRegex = compileRegexes(value.Text)
+		app.DOMRegex = compileDOMRegexes(value.DOM)
+		app.ImpliesResolved = parseImplies(value.Implies)
+
 		app.CatNames = make([]string, 0)
 
 		for _, cid := range app.Cats {
@@ -158,9 +353,25 @@ func (wa *WebAnalyzer) loadApps(r io.Reader) error {
 
 	}
 
+	wa.appDefs.index = buildPrefilterIndex(wa.appDefs.Apps)
+
 	return nil
 }
 
+// Candidates returns the names of apps whose regexes are worth running
+// against content (a response body, header value, script blob, ...),
+// narrowed down by the literal prefilter built when the schema was loaded.
+func (wa *WebAnalyzer) Candidates(content []byte) map[string]struct{} {
+	if wa.appDefs.index == nil {
+		candidates := make(map[string]struct{}, len(wa.appDefs.Apps))
+		for name := range wa.appDefs.Apps {
+			candidates[name] = struct{}{}
+		}
+		return candidates
+	}
+	return wa.appDefs.index.Candidates(content)
+}
+
 func compileNamedRegexes(from map[string]string) []AppRegexp {
 
 	var list []AppRegexp
@@ -194,6 +405,63 @@ func compileNamedRegexes(from map[string]string) []AppRegexp {
 	return list
 }
 
+// compileDOMRegexes flattens the dom selector map into one AppDOMRegexp per
+// checked condition: a plain existence check, or one entry per attribute
+// regex plus an optional text regex.
+func compileDOMRegexes(selectors DOMSelectors) []AppDOMRegexp {
+	var list []AppDOMRegexp
+
+	for sel, cond := range selectors {
+		if cond.Exists {
+			list = append(list, AppDOMRegexp{Selector: sel, Exists: true})
+			continue
+		}
+
+		for attr, value := range cond.Attributes {
+			splitted := strings.Split(value, "\\;")
+
+			r, err := regexp.Compile("(?i)" + splitted[0])
+			if err != nil {
+				continue
+			}
+
+			h := AppDOMRegexp{
+				Selector:  sel,
+				Attribute: attr,
+				AppRegexp: AppRegexp{Regexp: r},
+			}
+
+			if len(splitted) > 1 && strings.HasPrefix(splitted[1], "version:") {
+				h.Version = splitted[1][8:]
+			}
+
+			list = append(list, h)
+		}
+
+		if cond.Text != "" {
+			splitted := strings.Split(cond.Text, "\\;")
+
+			r, err := regexp.Compile("(?i)" + splitted[0])
+			if err != nil {
+				continue
+			}
+
+			h := AppDOMRegexp{
+				Selector:  sel,
+				AppRegexp: AppRegexp{Regexp: r},
+			}
+
+			if len(splitted) > 1 && strings.HasPrefix(splitted[1], "version:") {
+				h.Version = splitted[1][8:]
+			}
+
+			list = append(list, h)
+		}
+	}
+
+	return list
+}
+
 func compileRegexes(s StringArray) []AppRegexp {
 	var list []AppRegexp
 
@@ -211,7 +479,7 @@ func compileRegexes(s StringArray) []AppRegexp {
 				Regexp: regex,
 			}
 
-			if len(splitted) > 1 && strings.HasPrefix(splitted[0], "version") {
+			if len(splitted) > 1 && strings.HasPrefix(splitted[1], "version:") {
 				rv.Version = splitted[1][8:]
 			}
 